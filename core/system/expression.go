@@ -0,0 +1,378 @@
+package system
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// exprIdentifier enumerates the system indicators usable in an Expression rule.
+type exprIdentifier string
+
+const (
+	identLoad1       exprIdentifier = "load1"
+	identCpuUsage    exprIdentifier = "cpuUsage"
+	identAvgRT       exprIdentifier = "avgRT"
+	identInboundQPS  exprIdentifier = "inboundQPS"
+	identConcurrency exprIdentifier = "concurrency"
+)
+
+func isValidIdentifier(id exprIdentifier) bool {
+	switch id {
+	case identLoad1, identCpuUsage, identAvgRT, identInboundQPS, identConcurrency:
+		return true
+	default:
+		return false
+	}
+}
+
+// exprMetrics is a snapshot of the live system indicators an expression is
+// evaluated against, taken once per adaptive check so all comparisons in
+// the expression see a consistent view.
+type exprMetrics struct {
+	load1       float64
+	cpuUsage    float64
+	avgRT       float64
+	inboundQPS  float64
+	concurrency float64
+}
+
+func newExprMetrics(load1, cpuUsage, avgRT, inboundQPS, concurrency float64) exprMetrics {
+	return exprMetrics{
+		load1:       load1,
+		cpuUsage:    cpuUsage,
+		avgRT:       avgRT,
+		inboundQPS:  inboundQPS,
+		concurrency: concurrency,
+	}
+}
+
+func (m exprMetrics) value(id exprIdentifier) (float64, bool) {
+	switch id {
+	case identLoad1:
+		return m.load1, true
+	case identCpuUsage:
+		return m.cpuUsage, true
+	case identAvgRT:
+		return m.avgRT, true
+	case identInboundQPS:
+		return m.inboundQPS, true
+	case identConcurrency:
+		return m.concurrency, true
+	default:
+		return 0, false
+	}
+}
+
+// exprNode is one node of a parsed Expression rule's AST.
+type exprNode interface {
+	eval(m exprMetrics) bool
+}
+
+// compareNode is a leaf comparison, e.g. "cpuUsage > 0.8" or
+// "inboundQPS / concurrency > 100".
+type compareNode struct {
+	ident exprIdentifier
+	op    string
+	value float64
+
+	hasDiv     bool
+	divIsIdent bool
+	divIdent   exprIdentifier
+	divConst   float64
+}
+
+func (n *compareNode) eval(m exprMetrics) bool {
+	v, ok := m.value(n.ident)
+	if !ok {
+		return false
+	}
+	if n.hasDiv {
+		divisor := n.divConst
+		if n.divIsIdent {
+			dv, ok := m.value(n.divIdent)
+			if !ok {
+				return false
+			}
+			divisor = dv
+		}
+		if divisor == 0 {
+			return false
+		}
+		v = v / divisor
+	}
+	switch n.op {
+	case ">":
+		return v > n.value
+	case ">=":
+		return v >= n.value
+	case "<":
+		return v < n.value
+	case "<=":
+		return v <= n.value
+	case "==":
+		return v == n.value
+	case "!=":
+		return v != n.value
+	default:
+		return false
+	}
+}
+
+type notNode struct {
+	operand exprNode
+}
+
+func (n *notNode) eval(m exprMetrics) bool {
+	return !n.operand.eval(m)
+}
+
+// binaryNode is either an "&&" or "||" combination of two sub-expressions.
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *binaryNode) eval(m exprMetrics) bool {
+	if n.op == "&&" {
+		return n.left.eval(m) && n.right.eval(m)
+	}
+	return n.left.eval(m) || n.right.eval(m)
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokOp
+	tokSlash
+	tokAnd
+	tokOr
+	tokNot
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// parseExpression parses a boolean expression over the system indicators
+// into an AST, to be cached on the owning Rule and evaluated on every
+// adaptive check without re-parsing.
+func parseExpression(expr string) (exprNode, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, errors.Errorf("unexpected token %q in expression", p.peek().text)
+	}
+	return node, nil
+}
+
+func tokenizeExpression(expr string) ([]token, error) {
+	var tokens []token
+	i, n := 0, len(expr)
+	for i < n {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '&' && i+1 < n && expr[i+1] == '&':
+			tokens = append(tokens, token{tokAnd, "&&"})
+			i += 2
+		case c == '|' && i+1 < n && expr[i+1] == '|':
+			tokens = append(tokens, token{tokOr, "||"})
+			i += 2
+		case c == '!' && i+1 < n && expr[i+1] == '=':
+			tokens = append(tokens, token{tokOp, "!="})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, token{tokNot, "!"})
+			i++
+		case c == '=' && i+1 < n && expr[i+1] == '=':
+			tokens = append(tokens, token{tokOp, "=="})
+			i += 2
+		case c == '>' && i+1 < n && expr[i+1] == '=':
+			tokens = append(tokens, token{tokOp, ">="})
+			i += 2
+		case c == '>':
+			tokens = append(tokens, token{tokOp, ">"})
+			i++
+		case c == '<' && i+1 < n && expr[i+1] == '=':
+			tokens = append(tokens, token{tokOp, "<="})
+			i += 2
+		case c == '<':
+			tokens = append(tokens, token{tokOp, "<"})
+			i++
+		case c == '/':
+			tokens = append(tokens, token{tokSlash, "/"})
+			i++
+		case isIdentStart(c):
+			j := i + 1
+			for j < n && isIdentPart(expr[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, expr[i:j]})
+			i = j
+		case isDigit(c) || c == '.':
+			j := i + 1
+			for j < n && (isDigit(expr[j]) || expr[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, expr[i:j]})
+			i = j
+		default:
+			return nil, errors.Errorf("unexpected character %q in expression", string(c))
+		}
+	}
+	tokens = append(tokens, token{tokEOF, ""})
+	return tokens, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	identTok := p.next()
+	if identTok.kind != tokIdent {
+		return nil, errors.Errorf("expected identifier, got %q", identTok.text)
+	}
+	ident := exprIdentifier(identTok.text)
+	if !isValidIdentifier(ident) {
+		return nil, errors.Errorf("unknown identifier %q", identTok.text)
+	}
+	node := &compareNode{ident: ident}
+
+	if p.peek().kind == tokSlash {
+		p.next()
+		divTok := p.next()
+		switch divTok.kind {
+		case tokIdent:
+			divIdent := exprIdentifier(divTok.text)
+			if !isValidIdentifier(divIdent) {
+				return nil, errors.Errorf("unknown identifier %q", divTok.text)
+			}
+			node.hasDiv = true
+			node.divIsIdent = true
+			node.divIdent = divIdent
+		case tokNumber:
+			v, err := strconv.ParseFloat(divTok.text, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid number %q", divTok.text)
+			}
+			if v == 0 {
+				return nil, errors.New("division by zero in expression")
+			}
+			node.hasDiv = true
+			node.divConst = v
+		default:
+			return nil, errors.Errorf("expected identifier or number after '/', got %q", divTok.text)
+		}
+	}
+
+	opTok := p.next()
+	if opTok.kind != tokOp {
+		return nil, errors.Errorf("expected comparison operator, got %q", opTok.text)
+	}
+	node.op = opTok.text
+
+	numTok := p.next()
+	if numTok.kind != tokNumber {
+		return nil, errors.Errorf("expected number, got %q", numTok.text)
+	}
+	value, err := strconv.ParseFloat(numTok.text, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid number %q", numTok.text)
+	}
+	node.value = value
+
+	return node, nil
+}
+
+// checkExpression evaluates the rule's compiled expression against the
+// given metrics snapshot. It is invoked by DefaultAdaptiveSlot for rules
+// whose MetricType is Expression; it has no effect on other rule types.
+func (r *Rule) checkExpression(metrics exprMetrics) bool {
+	if r.compiledExpr == nil {
+		return false
+	}
+	return r.compiledExpr.eval(metrics)
+}
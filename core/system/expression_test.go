@@ -0,0 +1,56 @@
+package system
+
+import "testing"
+
+func TestParseExpressionEval(t *testing.T) {
+	cases := []struct {
+		expr    string
+		metrics exprMetrics
+		want    bool
+	}{
+		{"cpuUsage > 0.8", newExprMetrics(0, 0.9, 0, 0, 0), true},
+		{"cpuUsage > 0.8", newExprMetrics(0, 0.1, 0, 0, 0), false},
+		{"cpuUsage > 0.8 && inboundQPS > 500", newExprMetrics(0, 0.9, 0, 600, 0), true},
+		{"cpuUsage > 0.8 && inboundQPS > 500", newExprMetrics(0, 0.9, 0, 100, 0), false},
+		{"cpuUsage > 0.8 || load1 > 5", newExprMetrics(6, 0.1, 0, 0, 0), true},
+		{"!cpuUsage > 0.8", newExprMetrics(0, 0.9, 0, 0, 0), false},
+		{"inboundQPS / concurrency > 100", newExprMetrics(0, 0, 0, 1000, 5), true},
+		{"inboundQPS / concurrency > 100", newExprMetrics(0, 0, 0, 100, 5), false},
+	}
+
+	for _, c := range cases {
+		node, err := parseExpression(c.expr)
+		if err != nil {
+			t.Fatalf("parseExpression(%q) returned error: %v", c.expr, err)
+		}
+		if got := node.eval(c.metrics); got != c.want {
+			t.Errorf("eval(%q, %+v) = %v, want %v", c.expr, c.metrics, got, c.want)
+		}
+	}
+}
+
+func TestParseExpressionInvalid(t *testing.T) {
+	cases := []string{
+		"unknownIdent > 1",
+		"cpuUsage ? 1",
+		"cpuUsage > 0.8 &&",
+		"cpuUsage / 0 > 1",
+		"cpuUsage / unknownIdent > 1",
+	}
+	for _, expr := range cases {
+		if _, err := parseExpression(expr); err == nil {
+			t.Errorf("parseExpression(%q): expected error, got nil", expr)
+		}
+	}
+}
+
+func TestCompareNodeDivisionByIdentifierZero(t *testing.T) {
+	node, err := parseExpression("inboundQPS / concurrency > 100")
+	if err != nil {
+		t.Fatalf("parseExpression returned error: %v", err)
+	}
+	metrics := newExprMetrics(0, 0, 0, 1000, 0)
+	if node.eval(metrics) {
+		t.Errorf("eval with zero-valued identifier divisor should be false, not panic or true")
+	}
+}
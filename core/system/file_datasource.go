@@ -0,0 +1,269 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+
+	"github.com/alibaba/sentinel-golang/logging"
+)
+
+// defaultDebounceInterval is the minimum time between two consecutive
+// reloads triggered by filesystem events, so a burst of writes to the
+// same file (e.g. an editor doing write-then-rename) only triggers a
+// single Reload.
+const defaultDebounceInterval = 200 * time.Millisecond
+
+// RuleFileProvider loads system rules from one or more JSON files on disk
+// and hot-reloads them whenever the files change, pushing the parsed rules
+// into LoadRules. Only JSON is supported; a file with a .yaml/.yml
+// extension (or any other non-JSON content) fails to parse on load/reload.
+// Its lifecycle is modeled after Prometheus' rule manager: callers Start it
+// with a context, optionally Update the watched file set at runtime, and
+// Stop it on shutdown.
+type RuleFileProvider struct {
+	mux     sync.Mutex
+	files   []string
+	watcher *fsnotify.Watcher
+
+	debounce time.Duration
+
+	reloadCh chan struct{}
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewRuleFileProvider creates a RuleFileProvider watching the given files.
+// Files may be glob patterns; they are expanded on every Update/Reload.
+func NewRuleFileProvider(files ...string) *RuleFileProvider {
+	return &RuleFileProvider{
+		files:    files,
+		debounce: defaultDebounceInterval,
+		reloadCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// SetDebounceInterval overrides the default debounce interval used to
+// coalesce bursts of filesystem events into a single reload. It must be
+// called before Start.
+func (p *RuleFileProvider) SetDebounceInterval(d time.Duration) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.debounce = d
+}
+
+// Start begins watching the configured files and performs an initial load.
+// It blocks the calling goroutine until ctx is cancelled or Stop is called,
+// so callers typically invoke it with `go provider.Start(ctx)`.
+func (p *RuleFileProvider) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "failed to create fsnotify watcher")
+	}
+	p.mux.Lock()
+	p.watcher = watcher
+	// stopCh is closed by Stop and never reopened, so a provider that is
+	// Start-ed again after a Stop needs a fresh one; otherwise run() would
+	// select on an already-closed channel and exit immediately.
+	p.stopCh = make(chan struct{})
+	p.doneCh = make(chan struct{})
+	p.mux.Unlock()
+
+	if err := p.watchFiles(); err != nil {
+		return err
+	}
+	if err := p.Reload(); err != nil {
+		logging.Error(err, "[RuleFileProvider] Initial load failed")
+	}
+
+	p.run(ctx)
+	return nil
+}
+
+func (p *RuleFileProvider) run(ctx context.Context) {
+	defer close(p.doneCh)
+	var debounceTimer *time.Timer
+	for {
+		select {
+		case event, ok := <-p.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			if debounceTimer == nil {
+				debounceTimer = time.AfterFunc(p.debounce, func() {
+					if err := p.Reload(); err != nil {
+						logging.Error(err, "[RuleFileProvider] Failed to reload system rules")
+					}
+				})
+			} else {
+				debounceTimer.Reset(p.debounce)
+			}
+		case err, ok := <-p.watcher.Errors:
+			if !ok {
+				return
+			}
+			logging.Error(err, "[RuleFileProvider] fsnotify watcher error")
+		case <-p.reloadCh:
+			if err := p.Reload(); err != nil {
+				logging.Error(err, "[RuleFileProvider] Failed to reload system rules")
+			}
+		case <-ctx.Done():
+			_ = p.watcher.Close()
+			return
+		case <-p.stopCh:
+			_ = p.watcher.Close()
+			return
+		}
+	}
+}
+
+// Stop terminates the watch loop and releases the underlying fsnotify
+// watcher. It is safe to call Stop more than once.
+func (p *RuleFileProvider) Stop() {
+	p.mux.Lock()
+	doneCh := p.doneCh
+	p.mux.Unlock()
+	if doneCh == nil {
+		return
+	}
+	select {
+	case <-p.stopCh:
+	default:
+		close(p.stopCh)
+	}
+	<-doneCh
+}
+
+// Update replaces the set of watched files (glob patterns are accepted)
+// and immediately re-parses their union, atomically swapping in the new
+// ruleset via LoadRules.
+func (p *RuleFileProvider) Update(files ...string) error {
+	p.mux.Lock()
+	p.files = files
+	p.mux.Unlock()
+
+	if p.watcher != nil {
+		if err := p.watchFiles(); err != nil {
+			return err
+		}
+	}
+	return p.Reload()
+}
+
+// Reload re-parses all currently configured files and atomically swaps in
+// the resulting ruleset. It is the method to invoke from a SIGHUP handler.
+func (p *RuleFileProvider) Reload() error {
+	p.mux.Lock()
+	files := append([]string(nil), p.files...)
+	p.mux.Unlock()
+
+	rules, err := parseRuleFiles(files)
+	if err != nil {
+		return err
+	}
+	_, err = LoadRules(rules)
+	return err
+}
+
+func (p *RuleFileProvider) watchFiles() error {
+	p.mux.Lock()
+	files := append([]string(nil), p.files...)
+	watcher := p.watcher
+	p.mux.Unlock()
+
+	expanded, err := expandGlobs(files)
+	if err != nil {
+		return err
+	}
+	dirs := make(map[string]struct{})
+	for _, f := range expanded {
+		dirs[filepath.Dir(f)] = struct{}{}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			return errors.Wrapf(err, "failed to watch directory %s", dir)
+		}
+	}
+	return nil
+}
+
+// parseRuleFiles reads and unmarshals the union of the given files (glob
+// patterns are expanded) into a single []*Rule slice. A per-file parse
+// error does not abort the whole load; all errors are aggregated and
+// returned so the caller can decide whether to apply the partial result.
+func parseRuleFiles(files []string) ([]*Rule, error) {
+	expanded, err := expandGlobs(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []*Rule
+	var errs []string
+	for _, f := range expanded {
+		fileRules, err := parseRuleFile(f)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "file %s", f).Error())
+			continue
+		}
+		rules = append(rules, fileRules...)
+	}
+	if len(errs) > 0 {
+		return rules, errors.New("errors parsing system rule files: " + joinErrs(errs))
+	}
+	return rules, nil
+}
+
+func parseRuleFile(file string) ([]*Rule, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func expandGlobs(patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]struct{})
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid glob pattern %s", pattern)
+		}
+		if matches == nil {
+			matches = []string{pattern}
+		}
+		for _, m := range matches {
+			if _, ok := seen[m]; ok {
+				continue
+			}
+			seen[m] = struct{}{}
+			files = append(files, m)
+		}
+	}
+	return files, nil
+}
+
+func joinErrs(errs []string) string {
+	out := ""
+	for i, e := range errs {
+		if i > 0 {
+			out += "; "
+		}
+		out += e
+	}
+	return out
+}
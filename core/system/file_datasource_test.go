@@ -0,0 +1,54 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTempRuleFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write temp rule file: %v", err)
+	}
+	return path
+}
+
+func TestParseRuleFilesUnion(t *testing.T) {
+	dir := t.TempDir()
+	writeTempRuleFile(t, dir, "a.json", `[{"id":"a","metricType":4,"triggerCount":0.5}]`)
+	writeTempRuleFile(t, dir, "b.json", `[{"id":"b","metricType":0,"triggerCount":1}]`)
+
+	rules, err := parseRuleFiles([]string{filepath.Join(dir, "*.json")})
+	if err != nil {
+		t.Fatalf("parseRuleFiles returned error: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules from the union of both files, got %d", len(rules))
+	}
+}
+
+func TestParseRuleFilesAggregatesPerFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTempRuleFile(t, dir, "good.json", `[{"id":"a","metricType":0,"triggerCount":1}]`)
+	writeTempRuleFile(t, dir, "bad.json", `not json`)
+
+	_, err := parseRuleFiles([]string{filepath.Join(dir, "*.json")})
+	if err == nil {
+		t.Fatal("expected an aggregated error when one of the files fails to parse")
+	}
+}
+
+func TestExpandGlobsDedupes(t *testing.T) {
+	dir := t.TempDir()
+	writeTempRuleFile(t, dir, "a.json", `[]`)
+
+	files, err := expandGlobs([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "a.json")})
+	if err != nil {
+		t.Fatalf("expandGlobs returned error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected overlapping patterns to be deduplicated, got %v", files)
+	}
+}
@@ -0,0 +1,71 @@
+package system
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// The following hold the latest system metrics snapshot, refreshed by the
+// system metric collector. AdaptiveSlot reads them on every Check without
+// locking. Each value is stored and loaded atomically on its own; the five
+// are not updated as one unit, so a snapshot taken while an update is in
+// progress can mix old and new values.
+var (
+	currentLoadBits        uint64
+	currentCpuUsageBits    uint64
+	currentAvgRTBits       uint64
+	currentInboundQPSBits  uint64
+	currentConcurrencyBits uint64
+)
+
+func loadMetric(bits *uint64) float64 {
+	return math.Float64frombits(atomic.LoadUint64(bits))
+}
+
+func storeMetric(bits *uint64, v float64) {
+	atomic.StoreUint64(bits, math.Float64bits(v))
+}
+
+// CurrentLoad returns the latest sampled system load1.
+func CurrentLoad() float64 {
+	return loadMetric(&currentLoadBits)
+}
+
+// CurrentCpuUsage returns the latest sampled system CPU usage, in [0.0, 1.0].
+func CurrentCpuUsage() float64 {
+	return loadMetric(&currentCpuUsageBits)
+}
+
+// CurrentAvgRT returns the latest sampled average response time of all
+// inbound requests.
+func CurrentAvgRT() float64 {
+	return loadMetric(&currentAvgRTBits)
+}
+
+// CurrentInboundQPS returns the latest sampled QPS of all inbound requests.
+func CurrentInboundQPS() float64 {
+	return loadMetric(&currentInboundQPSBits)
+}
+
+// CurrentConcurrency returns the latest sampled concurrency of all inbound
+// requests.
+func CurrentConcurrency() float64 {
+	return loadMetric(&currentConcurrencyBits)
+}
+
+// UpdateSystemMetrics is called by the system metric collector to publish a
+// new snapshot of the live system indicators for AdaptiveSlot to check
+// rules (including Expression rules) against.
+func UpdateSystemMetrics(load1, cpuUsage, avgRT, inboundQPS, concurrency float64) {
+	storeMetric(&currentLoadBits, load1)
+	storeMetric(&currentCpuUsageBits, cpuUsage)
+	storeMetric(&currentAvgRTBits, avgRT)
+	storeMetric(&currentInboundQPSBits, inboundQPS)
+	storeMetric(&currentConcurrencyBits, concurrency)
+}
+
+// currentExprMetrics takes a snapshot of the current system metrics for
+// evaluating system rules against.
+func currentExprMetrics() exprMetrics {
+	return newExprMetrics(CurrentLoad(), CurrentCpuUsage(), CurrentAvgRT(), CurrentInboundQPS(), CurrentConcurrency())
+}
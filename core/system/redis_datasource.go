@@ -0,0 +1,158 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+
+	"github.com/alibaba/sentinel-golang/logging"
+)
+
+// DynamicDataSource is a remote source of system rules that pushes updates
+// into LoadRules independently of RuleFileProvider, so a fleet of services
+// can share a single source of truth for adaptive system rules.
+type DynamicDataSource interface {
+	// Start begins reading from the datasource and blocks until ctx is
+	// cancelled or Close is called.
+	Start(ctx context.Context) error
+	// Close releases any resources held by the datasource.
+	Close() error
+}
+
+const (
+	defaultRedisMinBackoff = 500 * time.Millisecond
+	defaultRedisMaxBackoff = 30 * time.Second
+)
+
+// RedisDataSource is a DynamicDataSource backed by a Redis key holding the
+// current system rule set (as a JSON-encoded []*Rule) and a pub/sub channel
+// that is published to whenever the key changes. On startup, and after
+// every reconnect, it resyncs from the key so it never keeps serving stale
+// rules after a missed publish.
+type RedisDataSource struct {
+	client  *redis.Client
+	key     string
+	channel string
+
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mux    sync.Mutex
+	closed bool
+	cancel context.CancelFunc
+}
+
+// NewRedisDataSource creates a RedisDataSource that resyncs system rules
+// from key and listens for change notifications on channel.
+func NewRedisDataSource(client *redis.Client, key, channel string) *RedisDataSource {
+	return &RedisDataSource{
+		client:     client,
+		key:        key,
+		channel:    channel,
+		minBackoff: defaultRedisMinBackoff,
+		maxBackoff: defaultRedisMaxBackoff,
+	}
+}
+
+// Start connects to Redis, performs an initial resync from the configured
+// key, then subscribes to the pub/sub channel for updates. It reconnects
+// with exponential backoff on failure, resyncing from the key after every
+// reconnect, and blocks until ctx is cancelled or Close is called.
+func (r *RedisDataSource) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	r.mux.Lock()
+	if r.closed {
+		r.mux.Unlock()
+		cancel()
+		return errors.New("RedisDataSource is already closed")
+	}
+	r.cancel = cancel
+	r.mux.Unlock()
+
+	backoff := r.minBackoff
+	for {
+		err := r.runOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			logging.Error(err, "[RedisDataSource] subscription ended, reconnecting", "backoff", backoff.String())
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > r.maxBackoff {
+			backoff = r.maxBackoff
+		}
+	}
+}
+
+// runOnce resyncs from the key and then services the pub/sub channel until
+// the subscription breaks or ctx is cancelled.
+func (r *RedisDataSource) runOnce(ctx context.Context) error {
+	if err := r.resync(ctx); err != nil {
+		return err
+	}
+
+	sub := r.client.Subscribe(ctx, r.channel)
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return errors.Wrap(err, "failed to subscribe to redis channel")
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return errors.New("redis pub/sub channel closed")
+			}
+			if err := r.applyPayload([]byte(msg.Payload)); err != nil {
+				logging.Error(err, "[RedisDataSource] failed to apply rules from pub/sub message")
+			}
+		}
+	}
+}
+
+func (r *RedisDataSource) resync(ctx context.Context) error {
+	val, err := r.client.Get(ctx, r.key).Result()
+	if err == redis.Nil {
+		_, loadErr := LoadRules(nil)
+		return loadErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read system rules from redis")
+	}
+	return r.applyPayload([]byte(val))
+}
+
+func (r *RedisDataSource) applyPayload(payload []byte) error {
+	var rules []*Rule
+	if err := json.Unmarshal(payload, &rules); err != nil {
+		return errors.Wrap(err, "failed to decode system rules payload")
+	}
+	_, err := LoadRules(rules)
+	return err
+}
+
+// Close stops Start's reconnect loop and closes the underlying Redis client.
+func (r *RedisDataSource) Close() error {
+	r.mux.Lock()
+	r.closed = true
+	cancel := r.cancel
+	r.mux.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	return r.client.Close()
+}
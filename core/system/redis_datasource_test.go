@@ -0,0 +1,25 @@
+package system
+
+import "testing"
+
+func TestRedisDataSourceApplyPayload(t *testing.T) {
+	defer ClearRules()
+
+	ds := &RedisDataSource{}
+	payload := []byte(`[{"id":"r1","metricType":0,"triggerCount":1}]`)
+	if err := ds.applyPayload(payload); err != nil {
+		t.Fatalf("applyPayload returned error: %v", err)
+	}
+
+	loaded := GetRules()
+	if len(loaded) != 1 || loaded[0].ID != "r1" {
+		t.Errorf("expected the decoded rule to be loaded, got %+v", loaded)
+	}
+}
+
+func TestRedisDataSourceApplyPayloadInvalidJSON(t *testing.T) {
+	ds := &RedisDataSource{}
+	if err := ds.applyPayload([]byte("not json")); err == nil {
+		t.Fatal("expected an error for an invalid JSON payload")
+	}
+}
@@ -0,0 +1,84 @@
+package system
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MetricType represents the type of the system metric for adaptive
+// flow control.
+type MetricType int32
+
+const (
+	// Load represents system load1 in Linux.
+	Load MetricType = iota
+	// AvgRT represents the average response time of all inbound requests.
+	AvgRT
+	// Concurrency represents the concurrency of all inbound requests.
+	Concurrency
+	// InboundQPS represents the QPS of all inbound requests.
+	InboundQPS
+	// CpuUsage represents the CPU usage percentage of the system, in [0.0, 1.0].
+	CpuUsage
+	// Expression represents a boolean expression evaluated over a snapshot
+	// of the other system indicators, e.g. "cpuUsage > 0.8 && inboundQPS > 500".
+	Expression
+
+	MetricTypeSize
+)
+
+func (t MetricType) String() string {
+	switch t {
+	case Load:
+		return "load"
+	case AvgRT:
+		return "avgRT"
+	case Concurrency:
+		return "concurrency"
+	case InboundQPS:
+		return "inboundQPS"
+	case CpuUsage:
+		return "cpuUsage"
+	case Expression:
+		return "expression"
+	default:
+		return "undefined"
+	}
+}
+
+// Rule describes the trigger condition for the system adaptive strategy.
+// Adaptive strategy of Sentinel will trigger system protection according to
+// the current system status when any rule is satisfied.
+type Rule struct {
+	// ID represents the unique ID of the rule (optional).
+	ID string `json:"id,omitempty"`
+	// MetricType indicates the type of the trigger condition.
+	MetricType MetricType `json:"metricType"`
+	// TriggerCount is the threshold that triggers the adaptive strategy.
+	// It is not evaluated when MetricType is Expression, but IsValidSystemRule
+	// still rejects a negative value for every MetricType.
+	TriggerCount float64 `json:"triggerCount"`
+	// Expression is a boolean expression over the live system indicators
+	// (load1, cpuUsage, avgRT, inboundQPS, concurrency). It is only used
+	// when MetricType is Expression.
+	Expression string `json:"expression,omitempty"`
+
+	// compiledExpr caches the parsed Expression. It is set by
+	// IsValidSystemRule when the rule is loaded, so the Expression is parsed
+	// at load time rather than on every adaptive check.
+	compiledExpr exprNode
+}
+
+func (r *Rule) String() string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Sprintf("Rule{MetricType=%v, TriggerCount=%v, Expression=%v}", r.MetricType, r.TriggerCount, r.Expression)
+	}
+	return string(b)
+}
+
+// ResourceName returns the resource name bound to this system rule, used to
+// register the rule check slot for the corresponding MetricType.
+func (r *Rule) ResourceName() string {
+	return "$" + r.MetricType.String()
+}
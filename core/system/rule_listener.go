@@ -0,0 +1,159 @@
+package system
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/alibaba/sentinel-golang/logging"
+)
+
+// RuleUpdateListener is invoked every time a rule set is installed as the
+// active system rule set, with snapshots of the rule map before and after the
+// update. It is also invoked when the installed set equals the previous one.
+// Implementations must treat old and new as read-only.
+type RuleUpdateListener func(old, new RuleMap)
+
+// ListenerToken identifies a previously registered RuleUpdateListener.
+// Go func values are not comparable, so callers hold on to the token
+// returned by RegisterRuleUpdateListener rather than the listener itself
+// in order to unregister it later.
+type ListenerToken uint64
+
+var (
+	listenerMux      = new(sync.RWMutex)
+	listeners        = make(map[ListenerToken]RuleUpdateListener)
+	listenerTokenSeq uint64
+)
+
+// RegisterRuleUpdateListener registers a listener that is notified with the
+// previous and current RuleMap every time a rule set is installed via
+// LoadRules, LoadRulesWithReport, LoadRulesStrict or RuleTxn.Commit, whether
+// or not it differs from the previous one. The returned token can be passed
+// to UnregisterRuleUpdateListener
+// to remove the listener again.
+func RegisterRuleUpdateListener(listener RuleUpdateListener) ListenerToken {
+	token := ListenerToken(atomic.AddUint64(&listenerTokenSeq, 1))
+
+	listenerMux.Lock()
+	defer listenerMux.Unlock()
+	listeners[token] = listener
+	return token
+}
+
+// UnregisterRuleUpdateListener removes a listener previously registered
+// with RegisterRuleUpdateListener. It is a no-op if the token is unknown.
+func UnregisterRuleUpdateListener(token ListenerToken) {
+	listenerMux.Lock()
+	defer listenerMux.Unlock()
+	delete(listeners, token)
+}
+
+// ruleDiff captures which MetricTypes gained, lost or changed their rule set
+// between two RuleMap snapshots.
+type ruleDiff struct {
+	added    []MetricType
+	removed  []MetricType
+	modified []MetricType
+}
+
+func diffRuleMap(old, new RuleMap) ruleDiff {
+	var d ruleDiff
+	for mt, newRules := range new {
+		oldRules, exists := old[mt]
+		if !exists {
+			d.added = append(d.added, mt)
+			continue
+		}
+		if !ruleSliceEqual(oldRules, newRules) {
+			d.modified = append(d.modified, mt)
+		}
+	}
+	for mt := range old {
+		if _, exists := new[mt]; !exists {
+			d.removed = append(d.removed, mt)
+		}
+	}
+	return d
+}
+
+// ruleSliceEqual reports whether a and b contain the same rules, ignoring
+// order. mergeRules (rule_txn.go) folds rules through Go maps to apply
+// puts/deletes, so two merges of the same logical rule set can legitimately
+// come out in different slice orders; comparing element-by-index would
+// report those as "modified" even though nothing changed.
+func ruleSliceEqual(a, b []*Rule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := sortedRulesByKey(a)
+	bs := sortedRulesByKey(b)
+	for i := range as {
+		if !ruleEqual(as[i], bs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedRulesByKey returns a copy of rules sorted by the same fields
+// ruleEqual compares, so ruleSliceEqual can compare two slices position by
+// position regardless of the order they were built in.
+func sortedRulesByKey(rules []*Rule) []*Rule {
+	sorted := make([]*Rule, len(rules))
+	copy(sorted, rules)
+	sort.Slice(sorted, func(i, j int) bool {
+		return ruleSortKey(sorted[i]) < ruleSortKey(sorted[j])
+	})
+	return sorted
+}
+
+func ruleSortKey(r *Rule) string {
+	return r.ID + "\x00" + strconv.Itoa(int(r.MetricType)) + "\x00" +
+		strconv.FormatFloat(r.TriggerCount, 'g', -1, 64) + "\x00" + r.Expression
+}
+
+// ruleEqual compares the user-visible fields of two rules, explicitly
+// excluding any derived/cached state (such as a parsed expression) that is
+// recomputed from those fields. Comparing *Rule by value instead would
+// report a rule as "modified" on every reload purely because its cache was
+// rebuilt, even though nothing the user configured actually changed.
+func ruleEqual(a, b *Rule) bool {
+	return a.ID == b.ID && a.MetricType == b.MetricType && a.TriggerCount == b.TriggerCount &&
+		a.Expression == b.Expression
+}
+
+// notifyRuleUpdate dispatches the rule map transition to all registered
+// listeners. It must be called without holding ruleMapMux so a slow or
+// misbehaving listener cannot block readers/writers of the rule map.
+// Listener panics are recovered and logged so a bad observer cannot bring
+// down the caller of LoadRules.
+func notifyRuleUpdate(old, new RuleMap) {
+	d := diffRuleMap(old, new)
+	if len(d.added) > 0 || len(d.removed) > 0 || len(d.modified) > 0 {
+		logging.Info("[SystemRuleManager] System rule set changed", "added", d.added, "removed", d.removed, "modified", d.modified)
+	}
+
+	listenerMux.RLock()
+	snapshot := make([]RuleUpdateListener, 0, len(listeners))
+	for _, l := range listeners {
+		snapshot = append(snapshot, l)
+	}
+	listenerMux.RUnlock()
+
+	for _, l := range snapshot {
+		invokeListener(l, old, new)
+	}
+}
+
+func invokeListener(listener RuleUpdateListener, old, new RuleMap) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Error(errors.Errorf("panic: %v", r), "[SystemRuleManager] Recovered from panic in rule update listener")
+		}
+	}()
+	listener(old, new)
+}
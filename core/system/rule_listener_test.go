@@ -0,0 +1,41 @@
+package system
+
+import "testing"
+
+func TestDiffRuleMapNoOpReload(t *testing.T) {
+	old := RuleMap{
+		CpuUsage: {{ID: "r1", MetricType: CpuUsage, TriggerCount: 0.8}},
+	}
+	// Rebuilding the same rules from scratch must not be reported as a
+	// modification, even though the *Rule pointers differ.
+	reloaded := RuleMap{
+		CpuUsage: {{ID: "r1", MetricType: CpuUsage, TriggerCount: 0.8}},
+	}
+
+	d := diffRuleMap(old, reloaded)
+	if len(d.added) != 0 || len(d.removed) != 0 || len(d.modified) != 0 {
+		t.Errorf("expected no diff for an identical reload, got %+v", d)
+	}
+}
+
+func TestDiffRuleMapAddedRemovedModified(t *testing.T) {
+	old := RuleMap{
+		CpuUsage: {{ID: "r1", MetricType: CpuUsage, TriggerCount: 0.8}},
+		Load:     {{ID: "r2", MetricType: Load, TriggerCount: 1}},
+	}
+	next := RuleMap{
+		CpuUsage:   {{ID: "r1", MetricType: CpuUsage, TriggerCount: 0.9}},
+		InboundQPS: {{ID: "r3", MetricType: InboundQPS, TriggerCount: 100}},
+	}
+
+	d := diffRuleMap(old, next)
+	if len(d.modified) != 1 || d.modified[0] != CpuUsage {
+		t.Errorf("expected CpuUsage to be reported modified, got %+v", d.modified)
+	}
+	if len(d.added) != 1 || d.added[0] != InboundQPS {
+		t.Errorf("expected InboundQPS to be reported added, got %+v", d.added)
+	}
+	if len(d.removed) != 1 || d.removed[0] != Load {
+		t.Errorf("expected Load to be reported removed, got %+v", d.removed)
+	}
+}
@@ -17,8 +17,19 @@ var (
 	ruleMap           = make(RuleMap)
 	ruleMapMux        = new(sync.RWMutex)
 	ruleUpdateHandler = defaultRuleUpdateHandler
+
+	// ruleMapVersion is bumped every time ruleMap is swapped, under
+	// ruleMapMux. RuleTxn.Commit (rule_txn.go) uses it to detect whether the
+	// snapshot its merge was computed against is still current before
+	// installing the result, so two concurrent commits can't silently
+	// clobber one another.
+	ruleMapVersion uint64
 )
 
+// errRuleMapVersionStale is returned by applyRuleMapUpdate when the active
+// rule map changed since the snapshot the caller merged against was taken.
+var errRuleMapVersionStale = errors.New("system rule map changed concurrently")
+
 // GetRules returns all the rules based on copy.
 // It doesn't take effect for system module if user changes the rule.
 // GetRules need to compete system module's global lock and the high performance losses of copy,
@@ -70,18 +81,37 @@ func ClearRules() error {
 }
 
 func onRuleUpdate(r RuleMap) error {
+	return applyRuleMapUpdate(nil, r)
+}
+
+// applyRuleMapUpdate swaps r in as the active rule map. If expectedVersion
+// is non-nil, the swap is skipped in favor of errRuleMapVersionStale when
+// ruleMapVersion no longer matches it, i.e. some other writer has changed
+// ruleMap since expectedVersion was read. RuleTxn.Commit (rule_txn.go) uses
+// this to retry its merge against a fresh snapshot instead of overwriting a
+// concurrent change.
+func applyRuleMapUpdate(expectedVersion *uint64, r RuleMap) error {
 	start := util.CurrentTimeNano()
 	ruleMapMux.Lock()
-	defer func() {
+	if expectedVersion != nil && ruleMapVersion != *expectedVersion {
 		ruleMapMux.Unlock()
-		logging.Debug("[System onRuleUpdate] Time statistic(ns) for updating system rule", "timeCost", util.CurrentTimeNano()-start)
-		if len(r) > 0 {
-			logging.Info("[SystemRuleManager] System rules loaded", "rules", r)
-		} else {
-			logging.Info("[SystemRuleManager] System rules were cleared")
-		}
-	}()
+		return errRuleMapVersionStale
+	}
+	old := ruleMap
 	ruleMap = r
+	ruleMapVersion++
+	ruleMapMux.Unlock()
+
+	logging.Debug("[System onRuleUpdate] Time statistic(ns) for updating system rule", "timeCost", util.CurrentTimeNano()-start)
+	if len(r) > 0 {
+		logging.Info("[SystemRuleManager] System rules loaded", "rules", r)
+	} else {
+		logging.Info("[SystemRuleManager] System rules were cleared")
+	}
+
+	// Notify listeners after the write lock has been released so a slow or
+	// misbehaving listener cannot stall readers/writers of the rule map.
+	notifyRuleUpdate(old, r)
 	return nil
 }
 
@@ -125,6 +155,17 @@ func IsValidSystemRule(rule *Rule) error {
 	if rule.MetricType == CpuUsage && rule.TriggerCount > 1 {
 		return errors.New("invalid CPU usage, valid range is [0.0, 1.0]")
 	}
+
+	if rule.MetricType == Expression {
+		if rule.Expression == "" {
+			return errors.New("empty expression")
+		}
+		node, err := parseExpression(rule.Expression)
+		if err != nil {
+			return errors.Wrap(err, "invalid expression")
+		}
+		rule.compiledExpr = node
+	}
 	return nil
 }
 
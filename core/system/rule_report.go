@@ -0,0 +1,82 @@
+package system
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/alibaba/sentinel-golang/logging"
+)
+
+// RuleLoadError describes why a single rule was rejected by
+// LoadRulesWithReport or LoadRulesStrict.
+type RuleLoadError struct {
+	Index int
+	Rule  *Rule
+	Err   error
+}
+
+func (e *RuleLoadError) Error() string {
+	return fmt.Sprintf("system rule at index %d is invalid: %s", e.Index, e.Err.Error())
+}
+
+// LoadReport is the structured outcome of LoadRulesWithReport/LoadRulesStrict:
+// the rules that were accepted, and for each rejected rule, its original
+// index, the offending *Rule, and the validation error from IsValidSystemRule.
+type LoadReport struct {
+	Accepted []*Rule
+	Rejected []RuleLoadError
+}
+
+// HasRejected reports whether at least one rule failed validation.
+func (r LoadReport) HasRejected() bool {
+	return len(r.Rejected) > 0
+}
+
+// LoadRulesWithReport behaves like LoadRules, but instead of silently
+// dropping invalid rules it returns a LoadReport describing exactly which
+// rules were accepted and, for each rejected rule, why it failed
+// IsValidSystemRule. Accepted rules are still loaded; rejected rules are
+// excluded, matching LoadRules' existing best-effort semantics.
+func LoadRulesWithReport(rules []*Rule) (LoadReport, error) {
+	report := buildLoadReport(rules)
+
+	m := buildRuleMap(report.Accepted)
+	if err := ruleUpdateHandler(onRuleUpdate, m); err != nil {
+		logging.Error(err, "Fail to load rules in system.LoadRulesWithReport()", "rules", rules)
+		return report, err
+	}
+	return report, nil
+}
+
+// LoadRulesStrict behaves like LoadRulesWithReport, except the load is
+// rejected transactionally if any rule fails validation: no change is made
+// to the active rule set, and the returned report carries the rejections
+// so a CI/CD pipeline pushing rules can gate on them.
+func LoadRulesStrict(rules []*Rule) (LoadReport, error) {
+	report := buildLoadReport(rules)
+	if report.HasRejected() {
+		return report, errors.Errorf("rejected %d invalid system rule(s); load aborted", len(report.Rejected))
+	}
+
+	m := buildRuleMap(report.Accepted)
+	if err := ruleUpdateHandler(onRuleUpdate, m); err != nil {
+		logging.Error(err, "Fail to load rules in system.LoadRulesStrict()", "rules", rules)
+		return report, err
+	}
+	return report, nil
+}
+
+func buildLoadReport(rules []*Rule) LoadReport {
+	report := LoadReport{
+		Accepted: make([]*Rule, 0, len(rules)),
+	}
+	for i, rule := range rules {
+		if err := IsValidSystemRule(rule); err != nil {
+			report.Rejected = append(report.Rejected, RuleLoadError{Index: i, Rule: rule, Err: err})
+			continue
+		}
+		report.Accepted = append(report.Accepted, rule)
+	}
+	return report
+}
@@ -0,0 +1,53 @@
+package system
+
+import "testing"
+
+func TestLoadRulesWithReport(t *testing.T) {
+	defer ClearRules()
+
+	rules := []*Rule{
+		{ID: "ok", MetricType: CpuUsage, TriggerCount: 0.5},
+		{ID: "bad-type", MetricType: MetricTypeSize, TriggerCount: 1},
+		{ID: "bad-cpu", MetricType: CpuUsage, TriggerCount: 2},
+	}
+
+	report, err := LoadRulesWithReport(rules)
+	if err != nil {
+		t.Fatalf("LoadRulesWithReport returned error: %v", err)
+	}
+	if len(report.Accepted) != 1 {
+		t.Errorf("expected 1 accepted rule, got %d: %+v", len(report.Accepted), report.Accepted)
+	}
+	if len(report.Rejected) != 2 {
+		t.Fatalf("expected 2 rejected rules, got %d", len(report.Rejected))
+	}
+	if report.Rejected[0].Index != 1 || report.Rejected[1].Index != 2 {
+		t.Errorf("expected rejected indexes [1, 2], got [%d, %d]", report.Rejected[0].Index, report.Rejected[1].Index)
+	}
+
+	loaded := GetRules()
+	if len(loaded) != 1 {
+		t.Errorf("expected the accepted rule to be loaded, got %d rules", len(loaded))
+	}
+}
+
+func TestLoadRulesStrictRejectsWholeLoad(t *testing.T) {
+	defer ClearRules()
+
+	if _, err := LoadRules([]*Rule{{ID: "keep", MetricType: CpuUsage, TriggerCount: 0.5}}); err != nil {
+		t.Fatalf("LoadRules returned error: %v", err)
+	}
+
+	_, err := LoadRulesStrict([]*Rule{
+		{ID: "ok", MetricType: CpuUsage, TriggerCount: 0.5},
+		{ID: "bad", MetricType: CpuUsage, TriggerCount: 2},
+	})
+	if err == nil {
+		t.Fatal("expected LoadRulesStrict to reject a batch containing an invalid rule")
+	}
+
+	loaded := GetRules()
+	if len(loaded) != 1 || loaded[0].ID != "keep" {
+		t.Errorf("expected the active rule set to be untouched after a rejected strict load, got %+v", loaded)
+	}
+}
@@ -0,0 +1,174 @@
+package system
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/alibaba/sentinel-golang/logging"
+)
+
+// RuleTxn stages Put/Delete operations against the active system rule set
+// so multiple datasources (file, Redis, programmatic) can build up a set of
+// changes and apply them atomically, instead of each racing to push a full
+// replacement ruleset via LoadRules. Commit merges the staged changes onto
+// whatever rule set is live at commit time, validates the merged result as
+// a whole, and only swaps it in if validation passes; otherwise the active
+// rule set is left untouched.
+//
+// A RuleTxn may be used from multiple goroutines: Put, Delete, Commit and
+// Rollback are serialized by its own mutex.
+type RuleTxn struct {
+	mux sync.Mutex
+
+	// puts is keyed by rule.ID for addressable rules. Rule.ID is optional
+	// (see Rule), so a rule with an empty ID is instead staged under its own
+	// synthetic anon-N key: keying everything by rule.ID would collapse
+	// every ID-less Put onto the same "" entry and silently drop all but
+	// the last one.
+	puts      map[string]*Rule
+	putSeq    uint64
+	deletes   map[string]struct{}
+	committed bool
+}
+
+// BeginRuleTxn starts a new transaction with no staged changes.
+func BeginRuleTxn() *RuleTxn {
+	return &RuleTxn{
+		puts:    make(map[string]*Rule),
+		deletes: make(map[string]struct{}),
+	}
+}
+
+// Put stages an upsert of rule. A rule staged for deletion in this
+// transaction is un-staged if Put is called for the same ID afterwards.
+// Rules with an empty ID cannot be addressed by a later Put/Delete call, so
+// each one is staged independently rather than being merged with other
+// ID-less rules.
+func (t *RuleTxn) Put(rule *Rule) {
+	if rule == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if rule.ID == "" {
+		t.putSeq++
+		t.puts["\x00anon-"+strconv.FormatUint(t.putSeq, 10)] = rule
+		return
+	}
+	delete(t.deletes, rule.ID)
+	t.puts[rule.ID] = rule
+}
+
+// Delete stages removal of the rule with the given ID. A rule staged for
+// Put in this transaction is un-staged if Delete is called for the same ID
+// afterwards.
+func (t *RuleTxn) Delete(id string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	delete(t.puts, id)
+	t.deletes[id] = struct{}{}
+}
+
+// Commit merges the staged Put/Delete operations onto the rule set that is
+// live at commit time and runs full validation on the merged result.
+// Installing the result goes through ruleUpdateHandler like
+// LoadRules/LoadRulesWithReport/LoadRulesStrict, so a handler registered via
+// RegisterRuleUpdateHandler still sees transactional commits; the callback it
+// is given is a version-checked variant of onRuleUpdate. If validation fails, nothing is installed and Commit returns the
+// validation error.
+//
+// The snapshot of the live rule set that the merge is computed against is
+// taken without holding ruleMapMux for the whole merge/validate sequence
+// (validation parses every Expression rule, which is too slow to do under
+// the write lock). Instead the install is guarded by an optimistic version
+// check: if another Commit (e.g. from a different datasource) has installed
+// a newer rule set in the meantime, the install is rejected and the merge
+// is retried against the new snapshot, so two concurrent commits can no
+// longer silently clobber one another.
+func (t *RuleTxn) Commit() error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.committed {
+		return errors.New("system rule transaction already committed")
+	}
+
+	for {
+		ruleMapMux.RLock()
+		base := ruleMap
+		version := ruleMapVersion
+		ruleMapMux.RUnlock()
+
+		merged := mergeRules(base, t.puts, t.deletes)
+		report := buildLoadReport(merged)
+		if report.HasRejected() {
+			return errors.Errorf("rejected %d invalid system rule(s); transaction rolled back", len(report.Rejected))
+		}
+
+		newMap := buildRuleMap(report.Accepted)
+		err := ruleUpdateHandler(func(rules RuleMap) error {
+			return applyRuleMapUpdate(&version, rules)
+		}, newMap)
+		if err == errRuleMapVersionStale {
+			continue
+		}
+		if err != nil {
+			logging.Error(err, "Fail to commit system rule transaction", "puts", len(t.puts), "deletes", len(t.deletes))
+			return err
+		}
+		break
+	}
+
+	t.committed = true
+	return nil
+}
+
+// Rollback discards all staged changes without touching the active rule
+// set. Since Commit never installs a partially-applied or invalid result,
+// Rollback only needs to be called to abandon a transaction before it is
+// committed.
+func (t *RuleTxn) Rollback() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.puts = make(map[string]*Rule)
+	t.deletes = make(map[string]struct{})
+	t.committed = true
+}
+
+// mergeRules applies puts/deletes on top of the rules currently in base.
+// Deletes address rules by their (non-empty) ID. Rules without an ID
+// cannot be addressed by Put/Delete; both the base rules and the staged
+// puts that have no ID are carried over individually, rather than being
+// deduplicated, since puts is keyed by a synthetic key for those (see
+// RuleTxn.Put) and not by the empty rule ID they actually carry.
+func mergeRules(base RuleMap, puts map[string]*Rule, deletes map[string]struct{}) []*Rule {
+	byID := make(map[string]*Rule, len(puts))
+	var unaddressable []*Rule
+	for _, rules := range base {
+		for _, r := range rules {
+			if r.ID == "" {
+				unaddressable = append(unaddressable, r)
+				continue
+			}
+			byID[r.ID] = r
+		}
+	}
+	for id := range deletes {
+		delete(byID, id)
+	}
+	for _, r := range puts {
+		if r.ID == "" {
+			unaddressable = append(unaddressable, r)
+			continue
+		}
+		byID[r.ID] = r
+	}
+
+	merged := make([]*Rule, 0, len(byID)+len(unaddressable))
+	merged = append(merged, unaddressable...)
+	for _, r := range byID {
+		merged = append(merged, r)
+	}
+	return merged
+}
@@ -0,0 +1,50 @@
+package system
+
+import "testing"
+
+func TestMergeRulesIDLessPutsAreNotCollapsed(t *testing.T) {
+	txn := BeginRuleTxn()
+	txn.Put(&Rule{MetricType: Load, TriggerCount: 1})
+	txn.Put(&Rule{MetricType: Load, TriggerCount: 2})
+	txn.Put(&Rule{MetricType: Load, TriggerCount: 3})
+
+	merged := mergeRules(make(RuleMap), txn.puts, txn.deletes)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 ID-less staged rules to survive the merge, got %d: %v", len(merged), merged)
+	}
+}
+
+func TestMergeRulesPutAndDeleteByID(t *testing.T) {
+	base := RuleMap{
+		Load: {
+			{ID: "r1", MetricType: Load, TriggerCount: 1},
+			{ID: "r2", MetricType: Load, TriggerCount: 2},
+		},
+	}
+
+	txn := BeginRuleTxn()
+	txn.Put(&Rule{ID: "r1", MetricType: Load, TriggerCount: 10})
+	txn.Delete("r2")
+
+	merged := mergeRules(base, txn.puts, txn.deletes)
+	if len(merged) != 1 {
+		t.Fatalf("expected 1 rule after put+delete, got %d: %v", len(merged), merged)
+	}
+	if merged[0].ID != "r1" || merged[0].TriggerCount != 10 {
+		t.Errorf("expected updated rule r1 with TriggerCount 10, got %+v", merged[0])
+	}
+}
+
+func TestMergeRulesBaseRulesWithoutIDAreKept(t *testing.T) {
+	base := RuleMap{
+		Load: {
+			{MetricType: Load, TriggerCount: 1},
+			{MetricType: Load, TriggerCount: 2},
+		},
+	}
+
+	merged := mergeRules(base, make(map[string]*Rule), make(map[string]struct{}))
+	if len(merged) != 2 {
+		t.Fatalf("expected both ID-less base rules to be kept, got %d: %v", len(merged), merged)
+	}
+}
@@ -0,0 +1,63 @@
+package system
+
+import (
+	"github.com/alibaba/sentinel-golang/core/base"
+)
+
+// RuleCheckSlotOrder is the order value of AdaptiveSlot within the rule
+// check slot chain.
+const RuleCheckSlotOrder = 1000
+
+// DefaultAdaptiveSlot is the singleton AdaptiveSlot registered against every
+// resource covered by a system Rule (see buildRuleMap).
+var DefaultAdaptiveSlot = &AdaptiveSlot{}
+
+// AdaptiveSlot is the rule check slot that enforces system adaptive
+// protection: it takes a snapshot of the live system metrics and blocks the
+// request if any loaded system Rule is triggered by it.
+type AdaptiveSlot struct {
+}
+
+func (s *AdaptiveSlot) Order() uint32 {
+	return RuleCheckSlotOrder
+}
+
+func (s *AdaptiveSlot) Check(ctx *base.EntryContext) *base.TokenResult {
+	result := ctx.RuleCheckResult
+	rules := getRules()
+	if len(rules) == 0 {
+		return result
+	}
+
+	metrics := currentExprMetrics()
+	for _, rule := range rules {
+		if !s.triggered(rule, metrics) {
+			continue
+		}
+		msg := "system protection triggered by rule on " + rule.MetricType.String()
+		return result.BlockWithCause(base.BlockTypeSystemFlow, msg, rule, nil)
+	}
+	return result
+}
+
+// triggered reports whether rule is tripped by the given metrics snapshot.
+// Expression rules evaluate their cached AST; the remaining metric types
+// compare the corresponding indicator against TriggerCount.
+func (s *AdaptiveSlot) triggered(rule *Rule, metrics exprMetrics) bool {
+	switch rule.MetricType {
+	case Load:
+		return metrics.load1 > rule.TriggerCount
+	case AvgRT:
+		return metrics.avgRT > rule.TriggerCount
+	case Concurrency:
+		return metrics.concurrency > rule.TriggerCount
+	case InboundQPS:
+		return metrics.inboundQPS > rule.TriggerCount
+	case CpuUsage:
+		return metrics.cpuUsage > rule.TriggerCount
+	case Expression:
+		return rule.checkExpression(metrics)
+	default:
+		return false
+	}
+}